@@ -0,0 +1,71 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package example shows the minimum needed to plug a new container runtime into
+// chaos-daemon: implement runtime.ContainerRuntimeInfoClient, wrap its construction in
+// a runtime.RuntimeFactory, and Register it from an init(). Downstream users wiring in
+// a real runtime (Kata, gVisor/runsc, cri-dockerd, podman, ...) can copy this layout
+// into their own package; it doesn't need to live under chaos-mesh at all.
+package example
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/chaos-mesh/pkg/chaosdaemon/runtime"
+)
+
+const runtimeName = "example"
+
+// Client is a ContainerRuntimeInfoClient that doesn't talk to a real runtime; it only
+// demonstrates where a real implementation's methods would go.
+type Client struct{}
+
+// FormatContainerID is a no-op here; a real runtime would strip its protocol prefix.
+func (Client) FormatContainerID(ctx context.Context, containerID string) (string, error) {
+	return containerID, nil
+}
+
+// GetPidFromContainerID is unimplemented; a real runtime would look up the container's PID.
+func (Client) GetPidFromContainerID(ctx context.Context, containerID string) (uint32, error) {
+	return 0, fmt.Errorf("example runtime does not support GetPidFromContainerID")
+}
+
+// GetSandboxPidFromContainerID is unimplemented; a real runtime would resolve the pod sandbox's PID.
+func (Client) GetSandboxPidFromContainerID(ctx context.Context, containerID string) (uint32, error) {
+	return 0, fmt.Errorf("example runtime does not support GetSandboxPidFromContainerID")
+}
+
+// ListProcessesInContainer is unimplemented; a real runtime would list the container's processes.
+func (Client) ListProcessesInContainer(ctx context.Context, containerID string) ([]uint32, error) {
+	return nil, fmt.Errorf("example runtime does not support ListProcessesInContainer")
+}
+
+// ContainerKillByContainerID is unimplemented; a real runtime would kill the container.
+func (Client) ContainerKillByContainerID(ctx context.Context, containerID string) error {
+	return fmt.Errorf("example runtime does not support ContainerKillByContainerID")
+}
+
+type factory struct{}
+
+func (factory) Name() string {
+	return runtimeName
+}
+
+func (factory) New(cfg runtime.RuntimeConfig) (runtime.ContainerRuntimeInfoClient, error) {
+	return Client{}, nil
+}
+
+func init() {
+	runtime.Register(factory{})
+}
@@ -0,0 +1,151 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package runtime defines the pluggable interface chaos-daemon uses to talk to a
+// container runtime, and a registry that runtime implementations register themselves
+// into. Adding a new runtime (Kata, gVisor/runsc via a containerd shim, cri-dockerd,
+// podman, ...) means implementing RuntimeFactory and calling Register from an init(),
+// without touching this package or chaosdaemon.CreateContainerRuntimeInfoClient.
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// ContainerRuntimeInfoClient represents a struct which can give you information about container runtime
+type ContainerRuntimeInfoClient interface {
+	GetPidFromContainerID(ctx context.Context, containerID string) (uint32, error)
+	// GetSandboxPidFromContainerID returns the PID of the pod's pause/sandbox container
+	// that containerID shares its network namespace with. It falls back to the
+	// container's own PID when the container has no sandbox, e.g. it is not managed by
+	// Kubernetes. Chaos that enters the network namespace (netem, DNS, HTTP) should
+	// prefer this over GetPidFromContainerID, since operating on a sidecar's own PID is
+	// racy and may not share the netns at all.
+	GetSandboxPidFromContainerID(ctx context.Context, containerID string) (uint32, error)
+	// ListProcessesInContainer lists the pids of every process running inside
+	// containerID using a runtime-native API.
+	ListProcessesInContainer(ctx context.Context, containerID string) ([]uint32, error)
+	ContainerKillByContainerID(ctx context.Context, containerID string) error
+	FormatContainerID(ctx context.Context, containerID string) (string, error)
+}
+
+// RuntimeTLSConfig holds optional client TLS settings for talking to a container
+// runtime endpoint, e.g. a remote containerd or CRI server exposed over TCP.
+type RuntimeTLSConfig struct {
+	// CertFile and KeyFile are the client certificate/key used for mTLS. Both must be
+	// set together, or left empty for a plain (non-mutual) TLS connection.
+	CertFile string
+	KeyFile  string
+	// CAFile, if set, is used to verify the server certificate instead of the host's
+	// root CAs.
+	CAFile string
+	// ServerName overrides the server name used during the TLS handshake, useful when
+	// the endpoint is reached through an address that doesn't match the cert's SAN.
+	ServerName string
+}
+
+// RuntimeConfig describes how to connect to a container runtime. The zero value picks
+// each runtime's conventional Linux defaults.
+type RuntimeConfig struct {
+	// Runtime selects the registered RuntimeFactory by name, e.g. "docker", "containerd" or "crio".
+	Runtime string
+
+	// Endpoint is the runtime's socket/endpoint URL, e.g. "unix:///run/containerd/containerd.sock",
+	// "tcp://10.0.0.1:2376" for a remote runtime, or "npipe:////./pipe/containerd-containerd" on
+	// Windows. Defaults to the runtime's conventional local socket when empty.
+	Endpoint string
+
+	// ContainerdNamespace is the containerd namespace to operate in. Defaults to "k8s.io".
+	// Only used when Runtime is "containerd".
+	ContainerdNamespace string
+
+	// DockerAPIVersion pins the Docker API version to negotiate. Leave empty to let the
+	// client auto-negotiate. Only used when Runtime is "docker".
+	DockerAPIVersion string
+
+	// DialOptions are additional grpc.DialOption passed through to CRI-compatible
+	// runtime clients ("containerd" and "crio" both dial over gRPC).
+	DialOptions []grpc.DialOption
+
+	// TLS, if set, enables TLS when dialing a CRI-compatible runtime endpoint.
+	TLS *RuntimeTLSConfig
+}
+
+// RuntimeFactory builds a ContainerRuntimeInfoClient for a single named runtime.
+// Implementations register themselves with Register, typically from an init().
+type RuntimeFactory interface {
+	// Name is the RuntimeConfig.Runtime value this factory handles, e.g. "docker".
+	Name() string
+	// New builds a ContainerRuntimeInfoClient from cfg. cfg.Runtime is guaranteed to
+	// equal Name().
+	New(cfg RuntimeConfig) (ContainerRuntimeInfoClient, error)
+}
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]RuntimeFactory{}
+)
+
+// Register adds factory to the registry under factory.Name(), overwriting any factory
+// previously registered under that name. It is typically called from an init().
+func Register(factory RuntimeFactory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[factory.Name()] = factory
+}
+
+// deregister removes the factory registered under name, if any.
+func deregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(factories, name)
+}
+
+// WithRuntime registers factory and returns a cleanup function that removes it again.
+// It lets a test register a fake runtime for the duration of the test without relying
+// on the package-level mock.On hooks the concrete clients use.
+func WithRuntime(factory RuntimeFactory) (cleanup func()) {
+	Register(factory)
+	return func() {
+		deregister(factory.Name())
+	}
+}
+
+// New looks up cfg.Runtime in the registry and builds a client from cfg.
+func New(cfg RuntimeConfig) (ContainerRuntimeInfoClient, error) {
+	mu.RLock()
+	factory, ok := factories[cfg.Runtime]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported container runtime %q, supported runtimes are %v", cfg.Runtime, registeredNames())
+	}
+
+	return factory.New(cfg)
+}
+
+func registeredNames() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"context"
+	"testing"
+)
+
+// fakeClient is a minimal ContainerRuntimeInfoClient used only to prove a
+// RuntimeFactory registered via WithRuntime is resolved by New.
+type fakeClient struct{}
+
+func (fakeClient) GetPidFromContainerID(ctx context.Context, containerID string) (uint32, error) {
+	return 42, nil
+}
+
+func (fakeClient) GetSandboxPidFromContainerID(ctx context.Context, containerID string) (uint32, error) {
+	return 42, nil
+}
+
+func (fakeClient) ListProcessesInContainer(ctx context.Context, containerID string) ([]uint32, error) {
+	return []uint32{42}, nil
+}
+
+func (fakeClient) ContainerKillByContainerID(ctx context.Context, containerID string) error {
+	return nil
+}
+
+func (fakeClient) FormatContainerID(ctx context.Context, containerID string) (string, error) {
+	return containerID, nil
+}
+
+type fakeFactory struct{}
+
+func (fakeFactory) Name() string { return "fake" }
+
+func (fakeFactory) New(cfg RuntimeConfig) (ContainerRuntimeInfoClient, error) {
+	return fakeClient{}, nil
+}
+
+func TestWithRuntimeRegistersAndCleansUp(t *testing.T) {
+	cleanup := WithRuntime(fakeFactory{})
+	defer cleanup()
+
+	client, err := New(RuntimeConfig{Runtime: "fake"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pid, err := client.GetPidFromContainerID(context.Background(), "irrelevant")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pid != 42 {
+		t.Fatalf("got pid %d, want 42", pid)
+	}
+}
+
+func TestWithRuntimeCleanupDeregisters(t *testing.T) {
+	cleanup := WithRuntime(fakeFactory{})
+	cleanup()
+
+	if _, err := New(RuntimeConfig{Runtime: "fake"}); err == nil {
+		t.Fatal("expected an error after cleanup, got nil")
+	}
+}
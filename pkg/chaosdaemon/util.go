@@ -15,24 +15,28 @@ package chaosdaemon
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/containerd/containerd/runtime/v2/task"
-	"github.com/docker/docker/daemon/cluster/executor/container"
-	"google.golang.org/grpc"
 	"io/ioutil"
 	"net/http"
-	"os"
+	"net/url"
 	"os/exec"
 	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 
 	"github.com/containerd/containerd"
 	"github.com/docker/docker/api/types"
 	dockerclient "github.com/docker/docker/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	cri "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
 
+	"github.com/pingcap/chaos-mesh/pkg/chaosdaemon/runtime"
 	"github.com/pingcap/chaos-mesh/pkg/mock"
 	"github.com/pingcap/chaos-mesh/pkg/utils"
 )
@@ -40,33 +44,42 @@ import (
 const (
 	containerRuntimeDocker     = "docker"
 	containerRuntimeContainerd = "containerd"
-	containerRuntimeCRIO = "crio"
+	containerRuntimeCRIO       = "crio"
 
 	defaultDockerSocket  = "unix:///var/run/docker.sock"
 	dockerProtocolPrefix = "docker://"
 
-	// TODO(yeya24): make socket and ns configurable
 	defaultContainerdSocket  = "/run/containerd/containerd.sock"
 	containerdProtocolPrefix = "containerd://"
 	containerdDefaultNS      = "k8s.io"
 
+	// defaultCRIOSocket is the default CRI-O runtime endpoint.
+	defaultCRIOSocket  = "/var/run/crio/crio.sock"
 	crioProtocolPrefix = "crio://"
-	defaultCRIOSocket = ""
 
 	defaultProcPrefix = "/proc"
+
+	// criSandboxIDLabel is the label containerd attaches to a CRI-managed container to
+	// point at the pod's pause/sandbox container.
+	criSandboxIDLabel = "io.kubernetes.cri.sandbox-id"
 )
 
-// ContainerRuntimeInfoClient represents a struct which can give you information about container runtime
-type ContainerRuntimeInfoClient interface {
-	GetPidFromContainerID(ctx context.Context, containerID string) (uint32, error)
-	ContainerKillByContainerID(ctx context.Context, containerID string) error
-	FormatContainerID(ctx context.Context, containerID string) (string, error)
-}
+// ContainerRuntimeInfoClient represents a struct which can give you information about container runtime.
+// It is an alias of runtime.ContainerRuntimeInfoClient kept for backwards compatibility;
+// the interface and the runtime registry now live under pkg/chaosdaemon/runtime.
+type ContainerRuntimeInfoClient = runtime.ContainerRuntimeInfoClient
+
+// RuntimeConfig is an alias of runtime.RuntimeConfig, kept for backwards compatibility.
+type RuntimeConfig = runtime.RuntimeConfig
+
+// RuntimeTLSConfig is an alias of runtime.RuntimeTLSConfig, kept for backwards compatibility.
+type RuntimeTLSConfig = runtime.RuntimeTLSConfig
 
 // DockerClientInterface represents the DockerClient, it's used to simplify unit test
 type DockerClientInterface interface {
 	ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
 	ContainerKill(ctx context.Context, containerID, signal string) error
+	ContainerTop(ctx context.Context, containerID string, arguments []string) (types.ContainerProcessList, error)
 }
 
 // DockerClient can get information from docker
@@ -99,6 +112,32 @@ func (c DockerClient) GetPidFromContainerID(ctx context.Context, containerID str
 	return uint32(container.State.Pid), nil
 }
 
+// GetSandboxPidFromContainerID returns the PID of the pod's pause/sandbox container.
+// Docker has no first-class notion of a sandbox, so a container sharing another
+// container's network namespace (HostConfig.NetworkMode == "container:<id>", as sidecars
+// do) is resolved by recursing into that container until one with its own netns is found.
+func (c DockerClient) GetSandboxPidFromContainerID(ctx context.Context, containerID string) (uint32, error) {
+	id, err := c.FormatContainerID(ctx, containerID)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.sandboxPidFromID(ctx, id)
+}
+
+func (c DockerClient) sandboxPidFromID(ctx context.Context, id string) (uint32, error) {
+	container, err := c.client.ContainerInspect(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if mode := container.HostConfig.NetworkMode; mode.IsContainer() {
+		return c.sandboxPidFromID(ctx, mode.ConnectedContainer())
+	}
+
+	return uint32(container.State.Pid), nil
+}
+
 // ContainerdClientInterface represents the ContainerClient, it's used to simply unit test
 type ContainerdClientInterface interface {
 	LoadContainer(ctx context.Context, id string) (containerd.Container, error)
@@ -137,6 +176,47 @@ func (c ContainerdClient) GetPidFromContainerID(ctx context.Context, containerID
 	return task.Pid(), nil
 }
 
+// GetSandboxPidFromContainerID returns the PID of the pod's pause/sandbox container.
+// Containerd labels a CRI-managed container with its sandbox ID, so the sandbox
+// container's own task PID is used. Falls back to the container's PID when the label
+// is absent, e.g. the container is not managed by Kubernetes.
+func (c ContainerdClient) GetSandboxPidFromContainerID(ctx context.Context, containerID string) (uint32, error) {
+	id, err := c.FormatContainerID(ctx, containerID)
+	if err != nil {
+		return 0, err
+	}
+
+	container, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	labels, err := container.Labels(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sandboxID, ok := labels[criSandboxIDLabel]
+	if !ok {
+		task, err := container.Task(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		return task.Pid(), nil
+	}
+
+	sandbox, err := c.client.LoadContainer(ctx, sandboxID)
+	if err != nil {
+		return 0, err
+	}
+	task, err := sandbox.Task(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return task.Pid(), nil
+}
+
 // newDockerclient returns a dockerclient.NewClient with mock points
 func newDockerClient(host string, version string, client *http.Client, httpHeaders map[string]string) (DockerClientInterface, error) {
 	// Mock point to return error or mock client in unit test
@@ -165,31 +245,76 @@ func newContainerdClient(address string, opts ...containerd.ClientOpt) (Containe
 	return containerd.New(address, opts...)
 }
 
+// CRIClient can get information from any CRI-compatible container runtime, e.g.
+// containerd (via its CRI plugin), CRI-O, or Mirantis cri-dockerd. Unlike
+// ContainerdClient and DockerClient, which talk to a runtime's native API, CRIClient
+// only relies on the CRI protocol, so the same implementation backs all of them.
 type CRIClient struct {
 	client cri.RuntimeServiceClient
+
+	// runtimeName is a human readable name used in error messages, e.g. "crio".
+	runtimeName string
+	// protocolPrefix is the container ID protocol prefix used by this runtime,
+	// e.g. "crio://" for CRI-O or "containerd://" for containerd-cri.
+	protocolPrefix string
 }
 
-// newCRIClient returns a CRIO client
-func newCRIClient(address string) (*CRIClient, error) {
-	conn, err :=
+// newCRIClient dials a CRI-compatible runtime endpoint and verifies it actually
+// speaks the CRI protocol before returning. opts must include transport credentials
+// (e.g. via tlsDialOption); newCRIClient only supplies blocking dial + timeout defaults.
+func newCRIClient(address string, runtimeName string, protocolPrefix string, opts ...grpc.DialOption) (*CRIClient, error) {
+	dialOpts := append([]grpc.DialOption{grpc.WithBlock(),
+		grpc.WithUnaryInterceptor(utils.TimeoutClientInterceptor)}, opts...)
+
+	conn, err := grpc.Dial(address, dialOpts...)
 	if err != nil {
 		return nil, err
 	}
 
+	client := cri.NewRuntimeServiceClient(conn)
+	if _, err := client.Version(context.Background(), &cri.VersionRequest{}); err != nil {
+		return nil, fmt.Errorf("failed to verify CRI runtime at %s: %v", address, err)
+	}
+
 	return &CRIClient{
-		cri.NewRuntimeServiceClient(conn),
+		client:         client,
+		runtimeName:    runtimeName,
+		protocolPrefix: protocolPrefix,
 	}, nil
 }
 
 // FormatContainerID strips protocol prefix from the container ID
 func (c CRIClient) FormatContainerID(ctx context.Context, containerID string) (string, error) {
-	if len(containerID) < len(crioProtocolPrefix) {
-		return "", fmt.Errorf("container id %s is not a crio container id", containerID)
+	if len(containerID) < len(c.protocolPrefix) {
+		return "", fmt.Errorf("container id %s is not a %s container id", containerID, c.runtimeName)
+	}
+	if containerID[0:len(c.protocolPrefix)] != c.protocolPrefix {
+		return "", fmt.Errorf("expected %s but got %s", c.protocolPrefix, containerID[0:len(c.protocolPrefix)])
+	}
+	return containerID[len(c.protocolPrefix):], nil
+}
+
+// criContainerInfo is the subset of the JSON blob CRI runtimes put in
+// ContainerStatusResponse.Info["info"] (with ContainerStatusRequest.Verbose set) that
+// we care about. The rest of the blob is runtime-specific and ignored.
+type criContainerInfo struct {
+	Pid       uint32 `json:"pid"`
+	SandboxID string `json:"sandboxID"`
+}
+
+func parseCRIContainerInfo(info map[string]string) (criContainerInfo, error) {
+	data, ok := info["info"]
+	if !ok {
+		// this should not happen
+		return criContainerInfo{}, errors.New("no pid from CRI response")
 	}
-	if containerID[0:len(crioProtocolPrefix)] != crioProtocolPrefix {
-		return "", fmt.Errorf("expected %s but got %s", crioProtocolPrefix, containerID[0:len(crioProtocolPrefix)])
+
+	var containerInfo criContainerInfo
+	if err := json.Unmarshal([]byte(data), &containerInfo); err != nil {
+		return criContainerInfo{}, err
 	}
-	return containerID[len(crioProtocolPrefix):], nil
+
+	return containerInfo, nil
 }
 
 // GetPidFromContainerID fetches PID according to container id
@@ -198,59 +323,230 @@ func (c CRIClient) GetPidFromContainerID(ctx context.Context, containerID string
 	if err != nil {
 		return 0, err
 	}
-	res, err := c.client.ContainerStatus(ctx, cri.ContainerStatusRequest{ContainerId: containerID})
+	res, err := c.client.ContainerStatus(ctx, &cri.ContainerStatusRequest{ContainerId: id, Verbose: true})
 	if err != nil {
 		return 0, err
 	}
 
-	info := res.Info
-	pidString, ok := info["pid"]
-	if !ok {
-		// this should not happen
-		return 0, errors.New("no pid from CRI response")
+	containerInfo, err := parseCRIContainerInfo(res.Info)
+	if err != nil {
+		return 0, err
+	}
+
+	return containerInfo.Pid, nil
+}
+
+// GetSandboxPidFromContainerID returns the PID of the pod's pause/sandbox container.
+// The CRI protocol doesn't expose PodSandboxId on ContainerStatus, so it is read out of
+// the same verbose info JSON blob as the container's own PID, then resolved to a PID via
+// PodSandboxStatus.
+func (c CRIClient) GetSandboxPidFromContainerID(ctx context.Context, containerID string) (uint32, error) {
+	id, err := c.FormatContainerID(ctx, containerID)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := c.client.ContainerStatus(ctx, &cri.ContainerStatusRequest{ContainerId: id, Verbose: true})
+	if err != nil {
+		return 0, err
+	}
+
+	containerInfo, err := parseCRIContainerInfo(res.Info)
+	if err != nil {
+		return 0, err
+	}
+	if containerInfo.SandboxID == "" {
+		return containerInfo.Pid, nil
 	}
 
-	pid, err := strconv.Atoi(pidString)
+	sandboxRes, err := c.client.PodSandboxStatus(ctx, &cri.PodSandboxStatusRequest{PodSandboxId: containerInfo.SandboxID, Verbose: true})
 	if err != nil {
 		return 0, err
 	}
 
-	return pid, nil
+	sandboxInfo, err := parseCRIContainerInfo(sandboxRes.Info)
+	if err != nil {
+		return 0, err
+	}
+
+	return sandboxInfo.Pid, nil
 }
 
-// CreateContainerRuntimeInfoClient creates a container runtime information client.
-func CreateContainerRuntimeInfoClient(containerRuntime string) (ContainerRuntimeInfoClient, error) {
-	// TODO: support more container runtime
+// containerdDialAddress resolves a RuntimeConfig.Endpoint into the address
+// containerd.New expects, supporting unix://, tcp:// and npipe:// schemes so
+// chaos-daemon can talk to a remote containerd instance, matching the libcontainerd
+// "remote" client model.
+func containerdDialAddress(endpoint string) (string, error) {
+	if endpoint == "" {
+		return defaultContainerdSocket, nil
+	}
 
-	var cli ContainerRuntimeInfoClient
-	switch containerRuntime {
-	case containerRuntimeDocker:
-		client, err := newDockerClient(defaultDockerSocket, "", nil, nil)
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid containerd endpoint %s: %v", endpoint, err)
+	}
+
+	switch u.Scheme {
+	case "":
+		return endpoint, nil
+	case "unix":
+		return u.Path, nil
+	case "tcp":
+		return u.Host, nil
+	case "npipe":
+		return endpoint, nil
+	default:
+		return "", fmt.Errorf("unsupported containerd endpoint scheme %q", u.Scheme)
+	}
+}
+
+// criDialAddress resolves a RuntimeConfig.Endpoint into the address grpc.Dial expects
+// for a CRI-compatible runtime, stripping the tcp:// scheme gRPC's passthrough resolver
+// doesn't understand and leaving unix:// untouched since gRPC resolves it natively.
+func criDialAddress(endpoint string, defaultSocket string) (string, error) {
+	if endpoint == "" {
+		return defaultSocket, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %s: %v", endpoint, err)
+	}
+
+	switch u.Scheme {
+	case "", "unix":
+		return endpoint, nil
+	case "tcp":
+		return u.Host, nil
+	default:
+		return "", fmt.Errorf("unsupported endpoint scheme %q", u.Scheme)
+	}
+}
+
+// tlsDialOption builds the gRPC transport credentials for a CRI-compatible runtime
+// client, falling back to an insecure connection when tlsCfg is nil.
+func tlsDialOption(tlsCfg *RuntimeTLSConfig) (grpc.DialOption, error) {
+	if tlsCfg == nil {
+		return grpc.WithInsecure(), nil
+	}
+
+	var certificates []tls.Certificate
+	if tlsCfg.CertFile != "" && tlsCfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
 		if err != nil {
 			return nil, err
 		}
-		cli = DockerClient{client}
+		certificates = append(certificates, cert)
+	}
 
-	case containerRuntimeContainerd:
-		// TODO(yeya24): add more options?
-		client, err := newContainerdClient(defaultContainerdSocket, containerd.WithDefaultNamespace(containerdDefaultNS))
+	certPool, err := x509.SystemCertPool()
+	if err != nil || certPool == nil {
+		certPool = x509.NewCertPool()
+	}
+	if tlsCfg.CAFile != "" {
+		certPool = x509.NewCertPool()
+		ca, err := ioutil.ReadFile(tlsCfg.CAFile)
 		if err != nil {
 			return nil, err
 		}
-		cli = ContainerdClient{client}
+		if !certPool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to append CA certificate from %s", tlsCfg.CAFile)
+		}
+	}
 
-	case containerRuntimeCRIO:
-		conn, err := grpc.Dial(defaultCRIOSocket, grpc.WithInsecure(), grpc.WithBlock(),
-			grpc.WithUnaryInterceptor(utils.TimeoutClientInterceptor))
-		if err != nil {
-			return nil, err
-		cli = CRIClient{client: cri.NewRuntimeServiceClient(conn)}
+	return grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+		Certificates: certificates,
+		RootCAs:      certPool,
+		ServerName:   tlsCfg.ServerName,
+	})), nil
+}
 
-	default:
-		return nil, fmt.Errorf("only docker and containerd is supported, but got %s", containerRuntime)
+// runtimeDialOptions builds the gRPC dial options for cfg: TLS (or an insecure
+// connection when cfg.TLS is nil) plus any caller-supplied cfg.DialOptions. Shared by
+// every runtime that dials over gRPC ("containerd" and "crio").
+func runtimeDialOptions(cfg RuntimeConfig) ([]grpc.DialOption, error) {
+	transportOpt, err := tlsDialOption(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := append([]grpc.DialOption{transportOpt}, cfg.DialOptions...)
+	return opts, nil
+}
+
+// dockerFactory builds DockerClient instances and registers itself under "docker".
+type dockerFactory struct{}
+
+func (dockerFactory) Name() string { return containerRuntimeDocker }
+
+func (dockerFactory) New(cfg runtime.RuntimeConfig) (runtime.ContainerRuntimeInfoClient, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultDockerSocket
+	}
+	client, err := newDockerClient(endpoint, cfg.DockerAPIVersion, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return DockerClient{client}, nil
+}
+
+// containerdFactory builds ContainerdClient instances and registers itself under "containerd".
+type containerdFactory struct{}
+
+func (containerdFactory) Name() string { return containerRuntimeContainerd }
+
+func (containerdFactory) New(cfg runtime.RuntimeConfig) (runtime.ContainerRuntimeInfoClient, error) {
+	address, err := containerdDialAddress(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	ns := cfg.ContainerdNamespace
+	if ns == "" {
+		ns = containerdDefaultNS
+	}
+	dialOpts, err := runtimeDialOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newContainerdClient(address, containerd.WithDefaultNamespace(ns), containerd.WithDialOpts(dialOpts))
+	if err != nil {
+		return nil, err
+	}
+
+	return ContainerdClient{client}, nil
+}
+
+// crioFactory builds a CRIClient talking to CRI-O and registers itself under "crio".
+type crioFactory struct{}
+
+func (crioFactory) Name() string { return containerRuntimeCRIO }
+
+func (crioFactory) New(cfg runtime.RuntimeConfig) (runtime.ContainerRuntimeInfoClient, error) {
+	address, err := criDialAddress(cfg.Endpoint, defaultCRIOSocket)
+	if err != nil {
+		return nil, err
 	}
+	dialOpts, err := runtimeDialOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCRIClient(address, containerRuntimeCRIO, crioProtocolPrefix, dialOpts...)
+}
 
-	return cli, nil
+func init() {
+	runtime.Register(dockerFactory{})
+	runtime.Register(containerdFactory{})
+	runtime.Register(crioFactory{})
+}
+
+// CreateContainerRuntimeInfoClient creates a container runtime information client by
+// looking cfg.Runtime up in the runtime package's registry. See that package to plug in
+// a runtime without modifying this function.
+func CreateContainerRuntimeInfoClient(cfg RuntimeConfig) (ContainerRuntimeInfoClient, error) {
+	return runtime.New(cfg)
 }
 
 // GetNetnsPath returns network namespace path
@@ -309,6 +605,36 @@ func (c CRIClient) ContainerKillByContainerID(ctx context.Context, containerID s
 	return err
 }
 
+// parseProcStatLine parses a /proc/<pid>/stat line and returns its pid and ppid
+// fields. The comm field (2nd field) is wrapped in parens but isn't guaranteed to be
+// free of whitespace or parens itself (see `man 5 proc`), so a naive %d %s %s %d scan
+// misparses it; instead we locate the last ')' and read the fixed-format fields after
+// it, which is what the field is guaranteed not to contain.
+func parseProcStatLine(line string) (pid uint32, ppid uint32, err error) {
+	open := strings.IndexByte(line, '(')
+	closeIdx := strings.LastIndexByte(line, ')')
+	if open < 0 || closeIdx < 0 || closeIdx < open {
+		return 0, 0, fmt.Errorf("invalid stat line: %q", line)
+	}
+
+	pid64, err := strconv.ParseUint(strings.TrimSpace(line[:open]), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// fields after "comm) " are: state ppid ...
+	fields := strings.Fields(line[closeIdx+1:])
+	if len(fields) < 2 {
+		return 0, 0, fmt.Errorf("invalid stat line: %q", line)
+	}
+	ppid64, err := strconv.ParseUint(fields[1], 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return uint32(pid64), uint32(ppid64), nil
+}
+
 // GetChildProcesses will return all child processes's pid. Include all generations.
 func GetChildProcesses(ppid uint32) ([]uint32, error) {
 	procs, err := ioutil.ReadDir(defaultProcPrefix)
@@ -322,56 +648,222 @@ func GetChildProcesses(ppid uint32) ([]uint32, error) {
 	}
 
 	pairs := make(chan processPair)
-	done := make(chan bool)
 
-	go func() {
-		var wg sync.WaitGroup
+	var wg sync.WaitGroup
+	for _, proc := range procs {
+		_, err := strconv.ParseUint(proc.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+
+		statusPath := defaultProcPrefix + "/" + proc.Name() + "/stat"
 
-		for _, proc := range procs {
-			_, err := strconv.ParseUint(proc.Name(), 10, 32)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			data, err := ioutil.ReadFile(statusPath)
 			if err != nil {
-				continue
+				log.Error(err, "read status file error", "path", statusPath)
+				return
 			}
 
-			statusPath := defaultProcPrefix + "/" + proc.Name() + "/stat"
-
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-
-				reader, err := os.Open(statusPath)
-				if err != nil {
-					log.Error(err, "read status file error", "path", statusPath)
-					return
-				}
-
-				var (
-					pid    uint32
-					comm   string
-					state  string
-					parent uint32
-				)
-				// according to procfs's man page
-				fmt.Fscanf(reader, "%d %s %s %d", &pid, &comm, &state, &parent)
-
-				pairs <- processPair{
-					Pid:  pid,
-					Ppid: parent,
-				}
-			}()
-		}
+			pid, parent, err := parseProcStatLine(strings.TrimSpace(string(data)))
+			if err != nil {
+				log.Error(err, "parse status file error", "path", statusPath)
+				return
+			}
 
+			pairs <- processPair{
+				Pid:  pid,
+				Ppid: parent,
+			}
+		}()
+	}
+
+	// Closing pairs once every producer is done - rather than racing a separate done
+	// channel against pairs in a select - guarantees every pair sent is drained before
+	// we build the graph, and lets the range loop below terminate on its own.
+	go func() {
 		wg.Wait()
-		done <- true
+		close(pairs)
 	}()
 
 	processGraph := utils.NewGraph()
-	for {
-		select {
-		case pair := <-pairs:
-			processGraph.Insert(pair.Ppid, pair.Pid)
-		case <-done:
-			return processGraph.Flatten(ppid), nil
+	for pair := range pairs {
+		processGraph.Insert(pair.Ppid, pair.Pid)
+	}
+
+	return processGraph.Flatten(ppid), nil
+}
+
+// ListProcessesInContainer lists the pids of every process running inside containerID,
+// preferring the container runtime's native process-listing API over walking /proc.
+func (c DockerClient) ListProcessesInContainer(ctx context.Context, containerID string) ([]uint32, error) {
+	id, err := c.FormatContainerID(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	top, err := c.client.ContainerTop(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pidIndex := -1
+	for i, title := range top.Titles {
+		if title == "PID" {
+			pidIndex = i
+			break
+		}
+	}
+	if pidIndex < 0 {
+		return nil, errors.New("docker container top output has no PID column")
+	}
+
+	pids := make([]uint32, 0, len(top.Processes))
+	for _, proc := range top.Processes {
+		pid, err := strconv.ParseUint(proc[pidIndex], 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		pids = append(pids, uint32(pid))
+	}
+
+	return pids, nil
+}
+
+// ListProcessesInContainer lists the pids of every process running inside containerID,
+// preferring the container runtime's native process-listing API over walking /proc.
+func (c ContainerdClient) ListProcessesInContainer(ctx context.Context, containerID string) ([]uint32, error) {
+	id, err := c.FormatContainerID(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	container, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	task, err := container.Task(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	processes, err := task.Pids(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pids := make([]uint32, 0, len(processes))
+	for _, p := range processes {
+		pids = append(pids, p.Pid)
+	}
+
+	return pids, nil
+}
+
+// ErrProcessListingNotSupported is returned by ListProcessesInContainer when the
+// container's cgroup can't be resolved to a recognized v1/v2 layout, i.e. there is no
+// native way to list its processes. It is the only error GetPidsInContainer treats as
+// "fall back to /proc"; any other error (permission denied, container gone, gRPC
+// failure, ...) is a real failure and is returned as-is.
+var ErrProcessListingNotSupported = errors.New("container runtime does not support listing processes natively")
+
+// listPidsFromCgroupProcs lists the pids in the cgroup that pid belongs to by reading
+// its cgroup.procs file. This is the fallback used for CRI runtimes, which have no
+// process-listing call in the CRI protocol itself.
+func listPidsFromCgroupProcs(pid uint32) ([]uint32, error) {
+	cgroupData, err := ioutil.ReadFile(fmt.Sprintf("%s/%d/cgroup", defaultProcPrefix, pid))
+	if err != nil {
+		return nil, err
+	}
+
+	procsPath, err := cgroupProcsPathFromCgroupFile(string(cgroupData))
+	if err != nil {
+		return nil, err
+	}
+
+	procsData, err := ioutil.ReadFile(procsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePidsFromCgroupProcsData(string(procsData)), nil
+}
+
+// cgroupProcsPathFromCgroupFile derives the cgroup.procs path from the contents of a
+// /proc/<pid>/cgroup file, using the first entry present. Works for both cgroup v1
+// (per-controller hierarchies) and the cgroup v2 unified hierarchy.
+func cgroupProcsPathFromCgroupFile(cgroupFileContents string) (string, error) {
+	for _, line := range strings.Split(strings.TrimSpace(cgroupFileContents), "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		// cgroup v1 keys each line by the controlling subsystem(s), e.g. "cpu,cpuacct";
+		// cgroup v2's unified hierarchy leaves the subsystem field empty.
+		subsystemDir := ""
+		if fields[1] != "" {
+			subsystemDir = "/" + strings.SplitN(fields[1], ",", 2)[0]
 		}
+		return fmt.Sprintf("/sys/fs/cgroup%s%s/cgroup.procs", subsystemDir, fields[2]), nil
 	}
+
+	return "", ErrProcessListingNotSupported
+}
+
+// parsePidsFromCgroupProcsData parses the newline-separated pid list found in a
+// cgroup.procs file, skipping any line that isn't a valid pid.
+func parsePidsFromCgroupProcsData(procsFileContents string) []uint32 {
+	var pids []uint32
+	for _, line := range strings.Split(strings.TrimSpace(procsFileContents), "\n") {
+		if line == "" {
+			continue
+		}
+		p, err := strconv.ParseUint(line, 10, 32)
+		if err != nil {
+			continue
+		}
+		pids = append(pids, uint32(p))
+	}
+
+	return pids
+}
+
+// ListProcessesInContainer lists the pids of every process running inside containerID.
+// The CRI protocol has no native process-listing call, so this resolves the container's
+// PID and reads its cgroup's cgroup.procs file instead.
+func (c CRIClient) ListProcessesInContainer(ctx context.Context, containerID string) ([]uint32, error) {
+	pid, err := c.GetPidFromContainerID(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return listPidsFromCgroupProcs(pid)
+}
+
+// GetPidsInContainer returns the pids of every process running inside containerID. It
+// prefers cli's native ListProcessesInContainer, and falls back to walking /proc via
+// GetChildProcesses only when that returns ErrProcessListingNotSupported; any other
+// error (a real failure, not a missing capability) is returned to the caller instead of
+// being masked by an expensive whole-host /proc scan.
+func GetPidsInContainer(ctx context.Context, cli ContainerRuntimeInfoClient, containerID string) ([]uint32, error) {
+	pids, err := cli.ListProcessesInContainer(ctx, containerID)
+	if err == nil {
+		return pids, nil
+	}
+	if !errors.Is(err, ErrProcessListingNotSupported) {
+		return nil, err
+	}
+
+	log.Error(err, "container runtime does not support listing processes natively, falling back to /proc walk", "containerID", containerID)
+
+	pid, err := cli.GetPidFromContainerID(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetChildProcesses(pid)
 }
@@ -0,0 +1,170 @@
+// Copyright 2019 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chaosdaemon
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseProcStatLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		wantPid  uint32
+		wantPpid uint32
+		wantErr  bool
+	}{
+		{
+			name:     "normal comm",
+			line:     "1234 (bash) S 1 1234 1234 0 -1 4194304 0 0 0 0 0 0 0 0 20 0 1 0",
+			wantPid:  1234,
+			wantPpid: 1,
+		},
+		{
+			name:     "comm contains spaces and parens",
+			line:     "5678 (my (weird) proc) S 42 5678 5678 0 -1 4194304 0 0 0 0 0 0 0 0 20 0 1 0",
+			wantPid:  5678,
+			wantPpid: 42,
+		},
+		{
+			name:    "missing parens",
+			line:    "1234 bash S 1",
+			wantErr: true,
+		},
+		{
+			name:    "too few fields after comm",
+			line:    "1234 (bash) S",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid, ppid, err := parseProcStatLine(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got pid=%d ppid=%d", pid, ppid)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pid != tt.wantPid || ppid != tt.wantPpid {
+				t.Fatalf("got pid=%d ppid=%d, want pid=%d ppid=%d", pid, ppid, tt.wantPid, tt.wantPpid)
+			}
+		})
+	}
+}
+
+func TestParseCRIContainerInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    map[string]string
+		want    criContainerInfo
+		wantErr bool
+	}{
+		{
+			name: "pid and sandboxID",
+			info: map[string]string{"info": `{"pid":4242,"sandboxID":"abc123"}`},
+			want: criContainerInfo{Pid: 4242, SandboxID: "abc123"},
+		},
+		{
+			name:    "missing info key",
+			info:    map[string]string{},
+			wantErr: true,
+		},
+		{
+			name:    "malformed json",
+			info:    map[string]string{"info": `not json`},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCRIContainerInfo(tt.info)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCgroupProcsPathFromCgroupFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "cgroup v2 unified hierarchy",
+			data: "0::/kubepods/burstable/pod123/container456\n",
+			want: "/sys/fs/cgroup/kubepods/burstable/pod123/container456/cgroup.procs",
+		},
+		{
+			name: "cgroup v1 per-subsystem hierarchy",
+			data: "5:cpu,cpuacct:/kubepods/burstable/pod123/container456\n4:memory:/kubepods/burstable/pod123/container456\n",
+			want: "/sys/fs/cgroup/cpu/kubepods/burstable/pod123/container456/cgroup.procs",
+		},
+		{
+			name:    "no recognizable entry",
+			data:    "garbage\n",
+			wantErr: ErrProcessListingNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cgroupProcsPathFromCgroupFile(tt.data)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got err %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePidsFromCgroupProcsData(t *testing.T) {
+	got := parsePidsFromCgroupProcsData("1\n2\n\nnot-a-pid\n3\n")
+	want := []uint32{1, 2, 3}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}